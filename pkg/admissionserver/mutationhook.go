@@ -0,0 +1,195 @@
+package admissionserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"image-scan-webhook/pkg/registry"
+	"image-scan-webhook/pkg/sbom"
+	"image-scan-webhook/pkg/transport"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog"
+
+	"k8s.io/api/admission/v1beta1"
+)
+
+const sbomConfigMapNamespace = "image-scan-webhook"
+
+// Initialize builds the Kubernetes clientset used to persist generated
+// SBOMs as ConfigMaps.
+func (h *mutationHook) Initialize(kubeClientConfig *rest.Config, stopCh <-chan struct{}) error {
+	client, err := kubernetes.NewForConfig(kubeClientConfig)
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes client: %v", err)
+	}
+
+	h.client = client
+	if h.sboms == nil {
+		h.sboms = sbom.NewGenerator()
+	}
+	if h.registry == nil {
+		h.registry = registry.NewClient(nil, transport.Config{})
+	}
+
+	return nil
+}
+
+// MutatingResource registers this hook against Pod admission, matching
+// admissionHook's ValidatingResource counterpart.
+func (h *mutationHook) MutatingResource() (schema.GroupVersionResource, string) {
+	return schema.GroupVersionResource{
+		Group:    "admission.image-scan-webhook.io",
+		Version:  "v1beta1",
+		Resource: "podsboms",
+	}, "podsbom"
+}
+
+// Admit generates an SBOM for each container image in the admitted Pod,
+// stamps a sbom.k8s.io/<container> annotation with its digest, and stores
+// the full SBOM in a ConfigMap for downstream tooling.
+func (h *mutationHook) Admit(request *v1beta1.AdmissionRequest) *v1beta1.AdmissionResponse {
+	if request.Resource.Resource != "pods" {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	var pod corev1.Pod
+	if err := json.Unmarshal(request.Object.Raw, &pod); err != nil {
+		return toAdmissionResponse(request.UID, fmt.Errorf("failed to decode Pod: %v", err))
+	}
+
+	annotations := map[string]string{}
+	for _, container := range pod.Spec.Containers {
+		digestAnnotation, err := h.annotateContainer(pod.Namespace, container.Image)
+		if err != nil {
+			klog.Errorf("[Mutation] failed to generate SBOM for %s: %v", container.Image, err)
+			continue
+		}
+		annotations[fmt.Sprintf("sbom.k8s.io/%s", container.Name)] = digestAnnotation
+	}
+
+	if len(annotations) == 0 {
+		return &v1beta1.AdmissionResponse{UID: request.UID, Allowed: true}
+	}
+
+	patch, err := annotationPatch(pod.Annotations, annotations)
+	if err != nil {
+		return toAdmissionResponse(request.UID, err)
+	}
+
+	patchType := v1beta1.PatchTypeJSONPatch
+	return &v1beta1.AdmissionResponse{
+		UID:       request.UID,
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
+
+// annotateContainer generates (or reuses the cached) SBOM for image, stores
+// it in a ConfigMap and returns the sha256 digest to annotate the Pod with.
+func (h *mutationHook) annotateContainer(namespace, image string) (string, error) {
+	// Resolve the immutable content digest first so the SBOM cache is keyed
+	// by the actual image content, not a moving tag like ":latest" that can
+	// point at a different image on every push.
+	imageDigest, err := h.registry.GetDigest(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest for %s: %v", image, err)
+	}
+
+	result, err := h.sboms.Generate(image, imageDigest)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(result.Document)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	if err := h.storeSBOM(namespace, digest, result.Document); err != nil {
+		klog.Errorf("[Mutation] failed to persist SBOM ConfigMap for %s: %v", image, err)
+	}
+
+	return digest, nil
+}
+
+// storeSBOM persists document as a ConfigMap named after digest. The
+// ConfigMap name is deterministic (one per image digest), so the overwhelmingly
+// common case of admitting another Pod that shares an already-seen image
+// hits an AlreadyExists error here; that's not a failure, the ConfigMap is
+// already there from the first Pod that triggered its creation.
+func (h *mutationHook) storeSBOM(namespace, digest string, document []byte) error {
+	name := "sbom-" + sanitizeConfigMapName(digest)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: sbomConfigMapNamespace,
+		},
+		BinaryData: map[string][]byte{
+			"sbom.cdx.json": document,
+		},
+	}
+
+	_, err := h.client.CoreV1().ConfigMaps(sbomConfigMapNamespace).Create(context.Background(), cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func sanitizeConfigMapName(digest string) string {
+	name := digest
+	for i, r := range name {
+		if r == ':' {
+			name = name[i+1:]
+			break
+		}
+	}
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	return name
+}
+
+// annotationPatch builds a JSON Patch that adds newAnnotations to the Pod
+// without clobbering existing ones: a JSON Patch "add" at an existing object
+// key replaces its value wholesale, so when the Pod already has annotations
+// we add each new key individually instead of replacing the whole map.
+func annotationPatch(existing, newAnnotations map[string]string) ([]byte, error) {
+	if len(existing) == 0 {
+		return json.Marshal([]map[string]interface{}{
+			{
+				"op":    "add",
+				"path":  "/metadata/annotations",
+				"value": newAnnotations,
+			},
+		})
+	}
+
+	patch := make([]map[string]interface{}, 0, len(newAnnotations))
+	for key, value := range newAnnotations {
+		patch = append(patch, map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations/" + jsonPointerEscape(key),
+			"value": value,
+		})
+	}
+	return json.Marshal(patch)
+}
+
+// jsonPointerEscape escapes a map key for use as a JSON Pointer path segment
+// (RFC 6901): '~' -> '~0', '/' -> '~1'.
+func jsonPointerEscape(key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	key = strings.ReplaceAll(key, "/", "~1")
+	return key
+}
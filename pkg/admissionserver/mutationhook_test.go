@@ -0,0 +1,109 @@
+package admissionserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSanitizeConfigMapName(t *testing.T) {
+	cases := []struct {
+		name   string
+		digest string
+		want   string
+	}{
+		{
+			name:   "strips the algorithm prefix",
+			digest: "sha256:abcd1234",
+			want:   "abcd1234",
+		},
+		{
+			name:   "truncates to 40 characters",
+			digest: "sha256:" + strings.Repeat("a", 64),
+			want:   strings.Repeat("a", 40),
+		},
+		{
+			name:   "passes through a digest with no colon",
+			digest: "abcd1234",
+			want:   "abcd1234",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sanitizeConfigMapName(tc.digest)
+			if got != tc.want {
+				t.Errorf("sanitizeConfigMapName(%q) = %q, want %q", tc.digest, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMutationHook_storeSBOM(t *testing.T) {
+	h := &mutationHook{client: fake.NewSimpleClientset()}
+
+	if err := h.storeSBOM("default", "sha256:abcd1234", []byte("{}")); err != nil {
+		t.Fatalf("storeSBOM returned error: %v", err)
+	}
+
+	cm, err := h.client.CoreV1().ConfigMaps(sbomConfigMapNamespace).Get(context.Background(), "sbom-abcd1234", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the ConfigMap to exist: %v", err)
+	}
+	if string(cm.BinaryData["sbom.cdx.json"]) != "{}" {
+		t.Errorf("BinaryData[sbom.cdx.json] = %q, want %q", cm.BinaryData["sbom.cdx.json"], "{}")
+	}
+}
+
+func TestMutationHook_storeSBOM_tolerateAlreadyExists(t *testing.T) {
+	h := &mutationHook{client: fake.NewSimpleClientset()}
+
+	if err := h.storeSBOM("default", "sha256:abcd1234", []byte("{}")); err != nil {
+		t.Fatalf("first storeSBOM returned error: %v", err)
+	}
+
+	// A second Pod admitted with the same image digest re-triggers storeSBOM
+	// against the same ConfigMap name; that must not surface as an error.
+	if err := h.storeSBOM("other-namespace", "sha256:abcd1234", []byte("{}")); err != nil {
+		t.Fatalf("second storeSBOM returned error: %v", err)
+	}
+}
+
+func TestAnnotationPatch_noExistingAnnotations(t *testing.T) {
+	patch, err := annotationPatch(nil, map[string]string{"sbom.k8s.io/app": "sha256:abcd"})
+	if err != nil {
+		t.Fatalf("annotationPatch returned error: %v", err)
+	}
+
+	if !strings.Contains(string(patch), `"path":"/metadata/annotations"`) {
+		t.Errorf("patch = %s, want a single add at /metadata/annotations", patch)
+	}
+}
+
+func TestAnnotationPatch_mergesWithExistingAnnotations(t *testing.T) {
+	existing := map[string]string{"team": "platform"}
+	patch, err := annotationPatch(existing, map[string]string{"sbom.k8s.io/app": "sha256:abcd"})
+	if err != nil {
+		t.Fatalf("annotationPatch returned error: %v", err)
+	}
+
+	got := string(patch)
+	if strings.Contains(got, `"path":"/metadata/annotations",`) {
+		t.Errorf("patch = %s, want per-key adds, not a whole-map replace", got)
+	}
+	if !strings.Contains(got, `/metadata/annotations/sbom.k8s.io~1app`) {
+		t.Errorf("patch = %s, want an escaped per-key path for sbom.k8s.io/app", got)
+	}
+}
+
+func TestJsonPointerEscape(t *testing.T) {
+	if got, want := jsonPointerEscape("sbom.k8s.io/app"), "sbom.k8s.io~1app"; got != want {
+		t.Errorf("jsonPointerEscape = %q, want %q", got, want)
+	}
+	if got, want := jsonPointerEscape("a~b"), "a~0b"; got != want {
+		t.Errorf("jsonPointerEscape = %q, want %q", got, want)
+	}
+}
@@ -2,18 +2,27 @@ package admissionserver
 
 import (
 	"image-scan-webhook/pkg/opaimagescanner"
+	"image-scan-webhook/pkg/registry"
+	"image-scan-webhook/pkg/sbom"
 
 	"k8s.io/api/admission/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 )
 
 type admissionHook struct {
 	evaluator opaimagescanner.AdmissionEvaluator
 }
 
+// mutationHook annotates admitted Pods with the SBOM generated for each of
+// their container images, storing the full SBOM as a ConfigMap for
+// downstream tooling to pick up.
 type mutationHook struct {
 	evaluator opaimagescanner.AdmissionEvaluator
+	sboms     *sbom.Generator
+	registry  *registry.Client
+	client    kubernetes.Interface
 }
 
 // toAdmissionResponse is a helper function to create an AdmissionResponse
@@ -2,69 +2,145 @@ package anchore
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
-	"time"
+
+	"image-scan-webhook/pkg/registry"
+	"image-scan-webhook/pkg/transport"
 
 	"k8s.io/klog"
 )
 
-func NewClient(baseUrl, secureToken string) (*AnchoreClient, error) {
+// ErrNotAnalyzed is returned by GetScanReport/CheckImage when Anchore has
+// been sent the image (via AddImage) but hasn't finished analyzing it yet.
+// Callers that can afford to wait, such as registryevents' pre-warm path,
+// should poll on this error rather than treating it as a scan failure.
+var ErrNotAnalyzed = errors.New("image not yet analyzed by anchore")
+
+// NewClient builds an AnchoreClient. registryCredentials is used by the
+// registry client to resolve image digests directly (see GetImageDigest);
+// tlsConfig controls how the Anchore connection itself is secured. Both may
+// be nil/zero for anonymous registry access and the system trust store.
+func NewClient(baseUrl, secureToken string, registryCredentials map[string]registry.Credential, tlsConfig transport.Config) (*AnchoreClient, error) {
 	client := AnchoreClient{
 		baseUrl:     baseUrl,
 		secureToken: secureToken,
+		registry:    registry.NewClient(registryCredentials, tlsConfig),
+		transport:   transport.NewFactory(tlsConfig),
 	}
 
 	return &client, nil
 }
 
-var (
-	transCfg = &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // ignore expired SSL certificates
-	}
-
-	client = &http.Client{
-		Transport: transCfg,
-	}
-)
-
 const errNotFound = "response from Anchore: 404"
 
+// retryableStatus are Anchore responses worth retrying; everything else
+// (4xx, successful 2xx) is returned to the caller immediately.
+func retryableStatus(statusCode int) bool {
+	return statusCode >= 500
+}
+
 func (c *AnchoreClient) anchoreRequest(path string, bodyParams map[string]string, method string) ([]byte, error) {
 	fullURL := c.baseUrl + path
 
-	bodyParamJson, err := json.Marshal(bodyParams)
-	req, err := http.NewRequest(method, fullURL, bytes.NewBuffer(bodyParamJson))
+	host, err := url.Parse(c.baseUrl)
 	if err != nil {
-		klog.Fatal(err)
+		return nil, fmt.Errorf("invalid Anchore base URL %q: %v", c.baseUrl, err)
 	}
 
-	req.SetBasicAuth(c.secureToken, "")
-	klog.Infof("[Anchore] Sending request to %s, with params %s", fullURL, bodyParams)
-	req.Header.Add("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
+	client, err := c.transport.ClientFor(host.Hostname())
+	if err != nil {
+		return nil, err
+	}
 
+	bodyParamJson, err := json.Marshal(bodyParams)
 	if err != nil {
-		return nil, fmt.Errorf("failed to complete request to Anchore: %v", err)
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
 	}
 
-	bodyText, err := ioutil.ReadAll(resp.Body)
+	var bodyText []byte
+	retryErr := transport.Retry(transport.DefaultRetryConfig, isRetryable, func() error {
+		req, reqErr := http.NewRequest(method, fullURL, bytes.NewBuffer(bodyParamJson))
+		if reqErr != nil {
+			return fmt.Errorf("failed to build request to Anchore: %v", reqErr)
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to complete request to Anchore: %v", err)
+		req.SetBasicAuth(c.secureToken, "")
+		klog.Infof("[Anchore] Sending request to %s, with params %s", fullURL, bodyParams)
+		req.Header.Add("Content-Type", "application/json")
+
+		resp, doErr := client.Do(req)
+		if doErr != nil {
+			// Network errors (refused connections, timeouts, ...) are just
+			// as worth retrying as a 5xx: both mean Anchore was reachable
+			// enough to attempt, but didn't answer this time.
+			return networkError{err: fmt.Errorf("failed to complete request to Anchore: %v", doErr)}
+		}
+		defer resp.Body.Close()
+
+		respBody, readErr := ioutil.ReadAll(resp.Body)
+		if readErr != nil {
+			return networkError{err: fmt.Errorf("failed to complete request to Anchore: %v", readErr)}
+		}
+
+		if resp.StatusCode != 200 {
+			return httpStatusError{statusCode: resp.StatusCode, err: fmt.Errorf("response from Anchore: %d", resp.StatusCode)}
+		}
+
+		bodyText = respBody
+		return nil
+	})
+
+	switch typedErr := retryErr.(type) {
+	case nil:
+		return bodyText, nil
+	case httpStatusError:
+		return nil, typedErr.err
+	case networkError:
+		return nil, typedErr.err
+	default:
+		return nil, retryErr
 	}
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("response from Anchore: %d", resp.StatusCode)
+}
+
+// isRetryable decides which anchoreRequest failures are worth another
+// attempt: 5xx responses and network-level errors/timeouts. 4xx responses
+// and anything else are returned to the caller immediately.
+func isRetryable(err error) bool {
+	switch typedErr := err.(type) {
+	case httpStatusError:
+		return retryableStatus(typedErr.statusCode)
+	case networkError:
+		return true
+	default:
+		return false
 	}
-	return bodyText, nil
 }
 
+// httpStatusError carries the response status code alongside the error so
+// the retry predicate can decide on 5xx without re-parsing error strings.
+type httpStatusError struct {
+	statusCode int
+	err        error
+}
+
+func (e httpStatusError) Error() string { return e.err.Error() }
+
+// networkError wraps a client.Do/body-read failure (connection refused,
+// timeout, connection reset, ...) so the retry predicate can distinguish it
+// from a non-retryable 4xx response.
+type networkError struct {
+	err error
+}
+
+func (e networkError) Error() string { return e.err.Error() }
+
 func (c *AnchoreClient) getReport(digest string, tag string) (*ScanReport, error) {
 	path := fmt.Sprintf("/images/%s/check?tag=%s&history=false&detail=true", digest, tag)
 	body, err := c.anchoreRequest(path, nil, "GET")
@@ -72,8 +148,7 @@ func (c *AnchoreClient) getReport(digest string, tag string) (*ScanReport, error
 	if err != nil && err.Error() == errNotFound {
 		// first time scanned image, return true
 		klog.Warningf("[Anchore] image %s with tag %s has not been scanned.", digest, tag)
-		//TODO: Report why the image is rejected
-		return nil, err
+		return nil, ErrNotAnalyzed
 	}
 
 	if err != nil {
@@ -131,30 +206,12 @@ func (c *AnchoreClient) getStatus(digest string, tag string) (bool, error) {
 	}
 }
 
-func (c *AnchoreClient) getDigest(imageRef string) (string, error) {
-	// Tag or repo??
-	params := map[string]string{
-		"tag":     imageRef,
-		"history": "true",
-	}
-
-	body, err := c.anchoreRequest("/images", params, "GET")
-	if err != nil {
-		klog.Errorf("[Anchore] %v", err)
-		return "", err
-	}
-
-	var images []Image
-	err = json.Unmarshal(body, &images)
-
-	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal JSON from response: %v", err)
-	}
-
-	return images[0].ImageDigest, nil
-}
-
-func (c *AnchoreClient) addImage(image string) error {
+// AddImage registers image with Anchore Engine so it starts fetching and
+// analyzing the manifest, without waiting for the result. Callers that need
+// the scan result should follow up with GetScanReport/CheckImage once
+// Anchore has had time to analyze the image; registryevents uses this to
+// pre-warm the scan on push, ahead of any admission request.
+func (c *AnchoreClient) AddImage(image string) error {
 	params := map[string]string{"tag": image}
 	_, err := c.anchoreRequest("/images", params, "POST")
 	if err != nil {
@@ -165,28 +222,30 @@ func (c *AnchoreClient) addImage(image string) error {
 	return nil
 }
 
-func (c *AnchoreClient) GetImageDigest(image string) (digest string, err error) {
-	err = c.addImage(image)
+// GetImageDigest resolves image to its content digest directly against the
+// registry's HTTP V2 API, instead of round-tripping through Anchore's
+// addImage/getDigest (which used to busy-poll until Anchore had itself
+// fetched and analyzed the manifest).
+//
+// It also registers the image with Anchore in the background: registryevents
+// pre-warms most images on push, but nothing guarantees that has happened
+// (first deploy, a registry with no notifications wired up, or this webhook
+// running without the listener at all), so the admission path still needs to
+// kick Anchore into scanning the image at least once.
+func (c *AnchoreClient) GetImageDigest(image string) (string, error) {
+	digest, err := c.registry.GetDigest(image)
 	if err != nil {
-		klog.Errorf("[Anchore] addImage error: %s", err)
-		return
+		klog.Errorf("[Registry] failed to resolve digest for %s: %v", image, err)
+		return "", err
 	}
 
-	count := 0
-	for {
-		digest, err = c.getDigest(image)
-		if err == nil {
-			return
-		}
-
-		klog.Errorf("[Anchore] getDigest error: %s", err)
-		if count >= 5 {
-			return
+	go func() {
+		if err := c.AddImage(image); err != nil {
+			klog.Warningf("[Anchore] background AddImage failed for %s: %v", image, err)
 		}
+	}()
 
-		time.Sleep(time.Second)
-		count++
-	}
+	return digest, nil
 }
 
 func (c *AnchoreClient) CheckImage(image string) (bool, error) {
@@ -202,5 +261,12 @@ func (c *AnchoreClient) GetScanReport(image string) (*ScanReport, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Unable to obtain image digest")
 	}
-	return c.getReport(digest, image)
+
+	report, err := c.getReport(digest, image)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Digest = digest
+	return report, nil
 }
@@ -0,0 +1,94 @@
+package anchore
+
+import (
+	"image-scan-webhook/pkg/registry"
+	"image-scan-webhook/pkg/transport"
+)
+
+// AnchoreClient talks to an Anchore Engine/Enterprise API instance.
+type AnchoreClient struct {
+	baseUrl     string
+	secureToken string
+
+	registry  *registry.Client
+	transport *transport.Factory
+}
+
+// Image is a single entry from Anchore's GET /images response.
+type Image struct {
+	ImageDigest string `json:"imageDigest"`
+}
+
+// ScanReport is Anchore's own per-image policy evaluation result, as returned
+// by GET /images/{digest}/check?...&detail=true.
+type ScanReport struct {
+	Status string `json:"status"`
+	// Digest is the content digest this report was evaluated against. It
+	// isn't part of Anchore's response body; AnchoreClient.GetScanReport
+	// fills it in from the digest it already resolved to build the request.
+	Digest string           `json:"-"`
+	Detail ScanReportDetail `json:"detail"`
+}
+
+// ScanReportDetail carries the per-gate-trigger policy evaluation rows
+// returned when the check request asks for detail=true.
+type ScanReportDetail struct {
+	Result struct {
+		Header []string   `json:"header"`
+		Rows   [][]string `json:"rows"`
+	} `json:"result"`
+}
+
+// ScanReports mirrors the nested shape of Anchore's check endpoint:
+// [ { <digest>: { <fullTag>: [ScanReport] } } ]
+type ScanReports []map[string]map[string][]ScanReport
+
+// GateFinding is a single policy gate/trigger result from a ScanReport's
+// detail rows, independent of the header's column order.
+type GateFinding struct {
+	Gate        string
+	Trigger     string
+	Action      string
+	Description string
+}
+
+// GateFindings decodes Detail's rows into GateFindings, looking up each
+// column by the names Anchore's check endpoint uses ("gate", "trigger",
+// "check_output", "gate_action"). If the header lacks "gate", "trigger" or
+// "gate_action", no findings are returned. Rows too short to hold those three
+// columns are skipped rather than erroring the whole report over one
+// malformed row; a missing "check_output" just leaves Description empty.
+func (r *ScanReport) GateFindings() []GateFinding {
+	columns := map[string]int{}
+	for i, name := range r.Detail.Result.Header {
+		columns[name] = i
+	}
+
+	gateIdx, hasGate := columns["gate"]
+	triggerIdx, hasTrigger := columns["trigger"]
+	actionIdx, hasAction := columns["gate_action"]
+	outputIdx, hasOutput := columns["check_output"]
+	if !hasGate || !hasTrigger || !hasAction {
+		return nil
+	}
+
+	findings := make([]GateFinding, 0, len(r.Detail.Result.Rows))
+	for _, row := range r.Detail.Result.Rows {
+		if gateIdx >= len(row) || triggerIdx >= len(row) || actionIdx >= len(row) {
+			continue
+		}
+
+		finding := GateFinding{
+			Gate:    row[gateIdx],
+			Trigger: row[triggerIdx],
+			Action:  row[actionIdx],
+		}
+		if hasOutput && outputIdx < len(row) {
+			finding.Description = row[outputIdx]
+		}
+
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
@@ -0,0 +1,40 @@
+// Package opaimagescanner evaluates admitted Pods' images against an OPA
+// policy bundle, using a scan backend from pkg/scanner to decide whether
+// each image passes.
+package opaimagescanner
+
+import (
+	"encoding/json"
+
+	"image-scan-webhook/pkg/scanner"
+)
+
+// AdmissionEvaluator decides whether an admission request's images are
+// allowed, given the scan results produced by the configured ImageScanner.
+type AdmissionEvaluator interface {
+	Evaluate(images []string) (*Decision, error)
+}
+
+// Decision is the outcome of evaluating an admission request's images.
+type Decision struct {
+	Allowed bool
+	Reason  string
+}
+
+// policyInput is the document handed to the OPA policy for a single image.
+// SBOM is kept top-level (input.sbom) rather than nested inside Scan, so
+// rules can match on it without caring which scanner backend produced the
+// rest of the report.
+type policyInput struct {
+	Image string              `json:"image"`
+	Scan  *scanner.ScanReport `json:"scan"`
+	SBOM  json.RawMessage     `json:"sbom,omitempty"`
+}
+
+func newPolicyInput(image string, report *scanner.ScanReport) policyInput {
+	input := policyInput{Image: image, Scan: report}
+	if report != nil && report.SBOM != nil {
+		input.SBOM = json.RawMessage(report.SBOM.Document)
+	}
+	return input
+}
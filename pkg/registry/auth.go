@@ -0,0 +1,143 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Credential is a single registry host's basic-auth credential, as found
+// under ".auths" in a Kubernetes dockerconfigjson secret.
+type Credential struct {
+	Username string
+	Password string
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+}
+
+// ParseDockerConfigJSON decodes a Kubernetes dockerconfigjson secret payload
+// into per-host credentials.
+func ParseDockerConfigJSON(data []byte) (map[string]Credential, error) {
+	var cfg dockerConfigJSON
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dockerconfigjson: %v", err)
+	}
+
+	creds := make(map[string]Credential, len(cfg.Auths))
+	for host, entry := range cfg.Auths {
+		username, password := entry.Username, entry.Password
+		if entry.Auth != "" {
+			decoded, err := decodeBasicAuth(entry.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode auth for %s: %v", host, err)
+			}
+			username, password = decoded[0], decoded[1]
+		}
+		creds[host] = Credential{Username: username, Password: password}
+	}
+
+	return creds, nil
+}
+
+func decodeBasicAuth(auth string) ([2]string, error) {
+	raw, err := base64.StdEncoding.DecodeString(auth)
+	if err != nil {
+		return [2]string{}, err
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return [2]string{}, fmt.Errorf("malformed auth string")
+	}
+
+	return [2]string{parts[0], parts[1]}, nil
+}
+
+// challenge is a parsed "WWW-Authenticate: Bearer ..." header.
+type challenge struct {
+	Realm   string
+	Service string
+	Scope   string
+}
+
+func parseBearerChallenge(header string) (*challenge, error) {
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, fmt.Errorf("unsupported WWW-Authenticate scheme: %s", header)
+	}
+
+	c := &challenge{}
+	for _, pair := range strings.Split(strings.TrimPrefix(header, "Bearer "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.Trim(kv[1], `"`)
+		switch strings.TrimSpace(kv[0]) {
+		case "realm":
+			c.Realm = value
+		case "service":
+			c.Service = value
+		case "scope":
+			c.Scope = value
+		}
+	}
+
+	if c.Realm == "" {
+		return nil, fmt.Errorf("WWW-Authenticate header missing realm: %s", header)
+	}
+
+	return c, nil
+}
+
+// fetchBearerToken exchanges a parsed challenge (and optional basic-auth
+// credential) for a bearer token via the realm's token endpoint.
+func fetchBearerToken(client *http.Client, c *challenge, cred *Credential) (string, error) {
+	req, err := http.NewRequest("GET", c.Realm, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %v", err)
+	}
+
+	q := req.URL.Query()
+	if c.Service != "" {
+		q.Set("service", c.Service)
+	}
+	if c.Scope != "" {
+		q.Set("scope", c.Scope)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	if cred != nil {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach token endpoint %s: %v", c.Realm, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint %s returned %d", c.Realm, resp.StatusCode)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to unmarshal token response: %v", err)
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,115 @@
+package registry
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseDockerConfigJSON(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	data := []byte(`{"auths":{"gcr.io":{"auth":"` + auth + `"},"docker.io":{"username":"u","password":"p"}}}`)
+
+	creds, err := ParseDockerConfigJSON(data)
+	if err != nil {
+		t.Fatalf("ParseDockerConfigJSON returned error: %v", err)
+	}
+
+	if got, want := creds["gcr.io"], (Credential{Username: "user", Password: "pass"}); got != want {
+		t.Errorf("creds[gcr.io] = %+v, want %+v", got, want)
+	}
+	if got, want := creds["docker.io"], (Credential{Username: "u", Password: "p"}); got != want {
+		t.Errorf("creds[docker.io] = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseDockerConfigJSON_malformedAuth(t *testing.T) {
+	data := []byte(`{"auths":{"gcr.io":{"auth":"not-base64!!"}}}`)
+	if _, err := ParseDockerConfigJSON(data); err == nil {
+		t.Fatal("expected an error for malformed auth, got nil")
+	}
+}
+
+func TestDecodeBasicAuth(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	decoded, err := decodeBasicAuth(encoded)
+	if err != nil {
+		t.Fatalf("decodeBasicAuth returned error: %v", err)
+	}
+	if decoded != [2]string{"user", "pass"} {
+		t.Errorf("decodeBasicAuth = %v, want [user pass]", decoded)
+	}
+}
+
+func TestDecodeBasicAuth_missingColon(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("userpass"))
+	if _, err := decodeBasicAuth(encoded); err == nil {
+		t.Fatal("expected an error for a string with no colon, got nil")
+	}
+}
+
+func TestParseBearerChallenge(t *testing.T) {
+	header := `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`
+
+	c, err := parseBearerChallenge(header)
+	if err != nil {
+		t.Fatalf("parseBearerChallenge returned error: %v", err)
+	}
+
+	if c.Realm != "https://auth.docker.io/token" {
+		t.Errorf("Realm = %q, want %q", c.Realm, "https://auth.docker.io/token")
+	}
+	if c.Service != "registry.docker.io" {
+		t.Errorf("Service = %q, want %q", c.Service, "registry.docker.io")
+	}
+	if c.Scope != "repository:library/nginx:pull" {
+		t.Errorf("Scope = %q, want %q", c.Scope, "repository:library/nginx:pull")
+	}
+}
+
+func TestParseBearerChallenge_missingRealm(t *testing.T) {
+	if _, err := parseBearerChallenge(`Bearer service="registry.docker.io"`); err == nil {
+		t.Fatal("expected an error for a challenge missing realm, got nil")
+	}
+}
+
+func TestParseBearerChallenge_unsupportedScheme(t *testing.T) {
+	if _, err := parseBearerChallenge(`Basic realm="registry"`); err == nil {
+		t.Fatal("expected an error for a non-Bearer scheme, got nil")
+	}
+}
+
+func TestFetchBearerToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("service") != "registry.docker.io" {
+			t.Errorf("expected service query param, got %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"token":"abc123"}`))
+	}))
+	defer server.Close()
+
+	c := &challenge{Realm: server.URL, Service: "registry.docker.io"}
+	token, err := fetchBearerToken(server.Client(), c, nil)
+	if err != nil {
+		t.Fatalf("fetchBearerToken returned error: %v", err)
+	}
+	if token != "abc123" {
+		t.Errorf("token = %q, want %q", token, "abc123")
+	}
+}
+
+func TestFetchBearerToken_accessTokenFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"xyz789"}`))
+	}))
+	defer server.Close()
+
+	token, err := fetchBearerToken(server.Client(), &challenge{Realm: server.URL}, nil)
+	if err != nil {
+		t.Fatalf("fetchBearerToken returned error: %v", err)
+	}
+	if token != "xyz789" {
+		t.Errorf("token = %q, want %q", token, "xyz789")
+	}
+}
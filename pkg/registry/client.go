@@ -0,0 +1,231 @@
+// Package registry implements just enough of the Docker Registry HTTP V2
+// protocol to resolve an image reference to its content digest directly,
+// without needing a scanner backend to fetch and analyze the manifest first.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"image-scan-webhook/pkg/transport"
+
+	"k8s.io/klog"
+)
+
+const (
+	manifestAccept = "application/vnd.docker.distribution.manifest.v2+json, " +
+		"application/vnd.oci.image.manifest.v1+json, " +
+		"application/vnd.docker.distribution.manifest.list.v2+json, " +
+		"application/vnd.oci.image.index.v1+json"
+
+	mediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeImageIndex   = "application/vnd.oci.image.index.v1+json"
+)
+
+// Platform identifies a manifest-list entry's target OS/architecture, in the
+// same vocabulary the registry API uses (e.g. "linux"/"amd64").
+type Platform struct {
+	OS           string
+	Architecture string
+}
+
+// DefaultPlatform is used when the caller doesn't know which node a Pod will
+// land on. It intentionally does NOT default to runtime.GOOS/GOARCH: that's
+// the webhook process's own platform, which has no relationship to the
+// node(s) in the cluster and would silently resolve the wrong manifest on
+// any cluster with mixed architectures.
+var DefaultPlatform = Platform{OS: "linux", Architecture: "amd64"}
+
+// Client resolves image references against their registry's HTTP V2 API.
+type Client struct {
+	transport *transport.Factory
+	// Credentials are per-registry-host basic-auth credentials, typically
+	// loaded from a dockerconfigjson Kubernetes secret via
+	// ParseDockerConfigJSON.
+	Credentials map[string]Credential
+	// Platform is the manifest-list entry selected when an image reference
+	// resolves to a multi-arch manifest list/OCI index and the caller has no
+	// more specific platform to resolve against (see GetDigestForPlatform).
+	// Defaults to DefaultPlatform.
+	Platform Platform
+}
+
+// NewClient builds a registry client. Pass the credentials decoded from a
+// dockerconfigjson secret (or nil for anonymous-only access) and the TLS
+// configuration to use per registry host (or a zero transport.Config for the
+// system trust store everywhere). Callers on a single-architecture cluster
+// that isn't linux/amd64 should set the returned Client's Platform field.
+func NewClient(credentials map[string]Credential, tlsConfig transport.Config) *Client {
+	return &Client{
+		transport:   transport.NewFactory(tlsConfig),
+		Credentials: credentials,
+		Platform:    DefaultPlatform,
+	}
+}
+
+type manifestListEntry struct {
+	Digest   string `json:"digest"`
+	Platform struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform"`
+}
+
+type manifestList struct {
+	Manifests []manifestListEntry `json:"manifests"`
+}
+
+// GetDigest resolves image to its content digest, selecting c.Platform's
+// entry if the reference turns out to be a multi-arch manifest list/OCI
+// index. Use GetDigestForPlatform when the caller knows the actual target
+// node's platform (e.g. from the Pod's node selector/affinity). A
+// digest-pinned reference is returned as pinned, without platform selection
+// (see GetDigestForPlatform).
+func (c *Client) GetDigest(image string) (string, error) {
+	return c.GetDigestForPlatform(image, c.Platform)
+}
+
+// GetDigestForPlatform resolves image to its content digest via a HEAD
+// request against the registry's manifest endpoint, falling back to a GET
+// and selecting the entry matching platform when the registry returns a
+// manifest list/OCI index instead of a single manifest. A digest-pinned
+// reference ("repo@sha256:...") is returned as pinned without contacting the
+// registry, so if it pins a manifest list/OCI index, that list's own digest
+// is returned and no platform entry is selected.
+func (c *Client) GetDigestForPlatform(image string, platform Platform) (string, error) {
+	ref := ParseReference(image)
+
+	if ref.Digest != "" {
+		// image already pins an exact content digest (e.g. GitOps/Helm
+		// pinning by digest); that's the answer, no registry round-trip
+		// needed.
+		return ref.Digest, nil
+	}
+
+	digest, contentType, err := c.headManifest(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if contentType == mediaTypeManifestList || contentType == mediaTypeImageIndex {
+		return c.resolvePlatformDigest(ref, platform)
+	}
+
+	return digest, nil
+}
+
+func (c *Client) headManifest(ref Reference) (digest string, contentType string, err error) {
+	resp, err := c.manifestRequest("HEAD", ref)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("registry %s returned %d for %s", ref.Host, resp.StatusCode, ref.Repository)
+	}
+
+	digest = resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", "", fmt.Errorf("registry %s did not return a Docker-Content-Digest header", ref.Host)
+	}
+
+	return digest, resp.Header.Get("Content-Type"), nil
+}
+
+func (c *Client) resolvePlatformDigest(ref Reference, platform Platform) (string, error) {
+	resp, err := c.manifestRequest("GET", ref)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s returned %d for %s", ref.Host, resp.StatusCode, ref.Repository)
+	}
+
+	var list manifestList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return "", fmt.Errorf("failed to unmarshal manifest list: %v", err)
+	}
+
+	for _, entry := range list.Manifests {
+		if entry.Platform.OS == platform.OS && entry.Platform.Architecture == platform.Architecture {
+			return entry.Digest, nil
+		}
+	}
+
+	return "", fmt.Errorf("manifest list for %s has no entry for platform %s/%s", ref.Repository, platform.OS, platform.Architecture)
+}
+
+// manifestRequest issues a request against /v2/<repository>/manifests/<tag>,
+// transparently handling the 401 -> Bearer challenge -> token -> retry flow.
+func (c *Client) manifestRequest(method string, ref Reference) (*http.Response, error) {
+	target := ref.Tag
+	if ref.Digest != "" {
+		target = ref.Digest
+	}
+	url := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Host, ref.Repository, target)
+
+	// transport.Config's PerHost overrides are keyed by hostname, so drop any
+	// ":port" from the registry host before looking up its client.
+	hostname := ref.Host
+	if h, _, splitErr := net.SplitHostPort(ref.Host); splitErr == nil {
+		hostname = h
+	}
+
+	httpClient, err := c.transport.ClientFor(hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request to %s: %v", ref.Host, err)
+	}
+	req.Header.Add("Accept", manifestAccept)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry %s: %v", ref.Host, err)
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	wwwAuth := resp.Header.Get("WWW-Authenticate")
+	c2, err := parseBearerChallenge(wwwAuth)
+	if err != nil {
+		return nil, fmt.Errorf("registry %s requires auth we don't support: %v", ref.Host, err)
+	}
+
+	var cred *Credential
+	if stored, ok := c.Credentials[ref.Host]; ok {
+		cred = &stored
+	}
+
+	token, err := fetchBearerToken(httpClient, c2, cred)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with %s: %v", ref.Host, err)
+	}
+
+	klog.Infof("[Registry] Authenticated with %s for %s", ref.Host, ref.Repository)
+
+	req, err = http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request to %s: %v", ref.Host, err)
+	}
+	req.Header.Add("Accept", manifestAccept)
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	resp, err = httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry %s: %v", ref.Host, err)
+	}
+
+	return resp, nil
+}
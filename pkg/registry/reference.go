@@ -0,0 +1,56 @@
+package registry
+
+import "strings"
+
+// Reference is a parsed image reference, e.g. "gcr.io/project/app:v1". Digest
+// is set only for digest-pinned references (e.g.
+// "gcr.io/project/app@sha256:..."), and takes precedence over Tag wherever a
+// reference needs to be addressed against the registry.
+type Reference struct {
+	Host       string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+const (
+	defaultHost = "registry-1.docker.io"
+	defaultTag  = "latest"
+)
+
+// ParseReference splits an image reference into registry host, repository
+// path and tag, applying the same defaults as the Docker CLI for bare
+// "library" images (e.g. "nginx" -> docker.io/library/nginx:latest).
+func ParseReference(image string) Reference {
+	ref := Reference{Host: defaultHost, Tag: defaultTag}
+
+	name := image
+	if idx := strings.LastIndex(name, "@"); idx != -1 {
+		// Digest-pinned references carry their own content digest; keep it so
+		// callers address the registry with it directly instead of falling
+		// back to whatever Tag defaults to.
+		ref.Digest = name[idx+1:]
+		name = name[:idx]
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.Contains(parts[0], ".") || strings.Contains(parts[0], ":") || parts[0] == "localhost") {
+		ref.Host = parts[0]
+		name = parts[1]
+	} else {
+		name = strings.Join(parts, "/")
+	}
+
+	if ref.Host == defaultHost && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+
+	if idx := strings.LastIndex(name, ":"); idx != -1 {
+		ref.Repository = name[:idx]
+		ref.Tag = name[idx+1:]
+	} else {
+		ref.Repository = name
+	}
+
+	return ref
+}
@@ -0,0 +1,66 @@
+package registry
+
+import "testing"
+
+func TestParseReference(t *testing.T) {
+	cases := []struct {
+		name  string
+		image string
+		want  Reference
+	}{
+		{
+			name:  "bare library image",
+			image: "nginx",
+			want:  Reference{Host: defaultHost, Repository: "library/nginx", Tag: defaultTag},
+		},
+		{
+			name:  "bare library image with tag",
+			image: "nginx:1.25",
+			want:  Reference{Host: defaultHost, Repository: "library/nginx", Tag: "1.25"},
+		},
+		{
+			name:  "docker.io user image is not library-prefixed",
+			image: "someuser/app:v1",
+			want:  Reference{Host: defaultHost, Repository: "someuser/app", Tag: "v1"},
+		},
+		{
+			name:  "private registry with bare repository keeps its own name",
+			image: "gcr.io/app:v1",
+			want:  Reference{Host: "gcr.io", Repository: "app", Tag: "v1"},
+		},
+		{
+			name:  "private registry with nested repository",
+			image: "gcr.io/project/app:v1",
+			want:  Reference{Host: "gcr.io", Repository: "project/app", Tag: "v1"},
+		},
+		{
+			name:  "registry host with port",
+			image: "localhost:5000/app:v1",
+			want:  Reference{Host: "localhost:5000", Repository: "app", Tag: "v1"},
+		},
+		{
+			name:  "no tag defaults to latest",
+			image: "gcr.io/project/app",
+			want:  Reference{Host: "gcr.io", Repository: "project/app", Tag: defaultTag},
+		},
+		{
+			name:  "digest-pinned reference preserves the digest",
+			image: "gcr.io/project/app@sha256:abcd1234",
+			want:  Reference{Host: "gcr.io", Repository: "project/app", Tag: defaultTag, Digest: "sha256:abcd1234"},
+		},
+		{
+			name:  "digest-pinned reference with a tag preserves both",
+			image: "gcr.io/project/app:v1@sha256:abcd1234",
+			want:  Reference{Host: "gcr.io", Repository: "project/app", Tag: "v1", Digest: "sha256:abcd1234"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ParseReference(tc.image)
+			if got != tc.want {
+				t.Errorf("ParseReference(%q) = %+v, want %+v", tc.image, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package registryevents
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"image-scan-webhook/pkg/scanner"
+)
+
+// Cache stores pre-warmed scan reports keyed by image digest.
+type Cache interface {
+	Get(digest string) (*scanner.ScanReport, bool)
+	Set(digest string, report *scanner.ScanReport, ttl time.Duration)
+}
+
+type cacheEntry struct {
+	digest    string
+	report    *scanner.ScanReport
+	expiresAt time.Time
+}
+
+// LRUCache is an in-memory, size-bounded cache of scan reports. It's the
+// default Cache and requires no external dependency; pair it with a
+// RedisCache (via MultiCache) to share pre-warmed results across replicas.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+var _ Cache = (*LRUCache)(nil)
+
+// NewLRUCache builds an in-memory cache holding at most capacity entries.
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *LRUCache) Get(digest string) (*scanner.ScanReport, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[digest]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.items, digest)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.report, true
+}
+
+func (c *LRUCache) Set(digest string, report *scanner.ScanReport, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[digest]; ok {
+		elem.Value.(*cacheEntry).report = report
+		elem.Value.(*cacheEntry).expiresAt = time.Now().Add(ttl)
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	entry := &cacheEntry{digest: digest, report: report, expiresAt: time.Now().Add(ttl)}
+	elem := c.order.PushFront(entry)
+	c.items[digest] = elem
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*cacheEntry).digest)
+		}
+	}
+}
+
+// MultiCache checks each cache in order on Get and writes through to all of
+// them on Set, so an in-memory LRUCache can be layered in front of a shared
+// RedisCache.
+type MultiCache struct {
+	caches []Cache
+}
+
+var _ Cache = (*MultiCache)(nil)
+
+// NewMultiCache layers the given caches, checked in order on Get.
+func NewMultiCache(caches ...Cache) *MultiCache {
+	return &MultiCache{caches: caches}
+}
+
+func (m *MultiCache) Get(digest string) (*scanner.ScanReport, bool) {
+	for _, c := range m.caches {
+		if report, ok := c.Get(digest); ok {
+			return report, true
+		}
+	}
+	return nil, false
+}
+
+func (m *MultiCache) Set(digest string, report *scanner.ScanReport, ttl time.Duration) {
+	for _, c := range m.caches {
+		c.Set(digest, report, ttl)
+	}
+}
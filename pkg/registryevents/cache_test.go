@@ -0,0 +1,135 @@
+package registryevents
+
+import (
+	"testing"
+	"time"
+
+	"image-scan-webhook/pkg/scanner"
+)
+
+func TestLRUCache_getSetRoundTrip(t *testing.T) {
+	c := NewLRUCache(2)
+	report := &scanner.ScanReport{Backend: "anchore"}
+
+	c.Set("sha256:a", report, time.Minute)
+
+	got, ok := c.Get("sha256:a")
+	if !ok {
+		t.Fatal("expected a hit for sha256:a")
+	}
+	if got != report {
+		t.Errorf("got %+v, want %+v", got, report)
+	}
+}
+
+func TestLRUCache_missForUnknownDigest(t *testing.T) {
+	c := NewLRUCache(2)
+	if _, ok := c.Get("sha256:missing"); ok {
+		t.Error("expected a miss for an unset digest")
+	}
+}
+
+func TestLRUCache_evictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("sha256:a", &scanner.ScanReport{Backend: "a"}, time.Minute)
+	c.Set("sha256:b", &scanner.ScanReport{Backend: "b"}, time.Minute)
+
+	// Touch "a" so "b" becomes the least recently used entry.
+	c.Get("sha256:a")
+
+	c.Set("sha256:c", &scanner.ScanReport{Backend: "c"}, time.Minute)
+
+	if _, ok := c.Get("sha256:b"); ok {
+		t.Error("expected sha256:b to have been evicted")
+	}
+	if _, ok := c.Get("sha256:a"); !ok {
+		t.Error("expected sha256:a to still be cached")
+	}
+	if _, ok := c.Get("sha256:c"); !ok {
+		t.Error("expected sha256:c to be cached")
+	}
+}
+
+func TestLRUCache_expiresEntriesPastTTL(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("sha256:a", &scanner.ScanReport{Backend: "a"}, -time.Second)
+
+	if _, ok := c.Get("sha256:a"); ok {
+		t.Error("expected an already-expired entry to miss")
+	}
+}
+
+func TestLRUCache_setOverwritesExistingEntryAndResetsTTL(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("sha256:a", &scanner.ScanReport{Backend: "old"}, time.Minute)
+	c.Set("sha256:a", &scanner.ScanReport{Backend: "new"}, time.Minute)
+
+	got, ok := c.Get("sha256:a")
+	if !ok {
+		t.Fatal("expected a hit for sha256:a")
+	}
+	if got.Backend != "new" {
+		t.Errorf("Backend = %q, want %q", got.Backend, "new")
+	}
+}
+
+type fakeCache struct {
+	data map[string]*scanner.ScanReport
+	gets int
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{data: make(map[string]*scanner.ScanReport)}
+}
+
+func (f *fakeCache) Get(digest string) (*scanner.ScanReport, bool) {
+	f.gets++
+	report, ok := f.data[digest]
+	return report, ok
+}
+
+func (f *fakeCache) Set(digest string, report *scanner.ScanReport, ttl time.Duration) {
+	f.data[digest] = report
+}
+
+func TestMultiCache_getChecksCachesInOrder(t *testing.T) {
+	first := newFakeCache()
+	second := newFakeCache()
+	second.data["sha256:a"] = &scanner.ScanReport{Backend: "second"}
+
+	m := NewMultiCache(first, second)
+
+	got, ok := m.Get("sha256:a")
+	if !ok {
+		t.Fatal("expected a hit")
+	}
+	if got.Backend != "second" {
+		t.Errorf("Backend = %q, want %q", got.Backend, "second")
+	}
+	if first.gets != 1 {
+		t.Errorf("first.gets = %d, want 1", first.gets)
+	}
+}
+
+func TestMultiCache_setWritesThroughToAllCaches(t *testing.T) {
+	first := newFakeCache()
+	second := newFakeCache()
+	m := NewMultiCache(first, second)
+
+	report := &scanner.ScanReport{Backend: "anchore"}
+	m.Set("sha256:a", report, time.Minute)
+
+	if first.data["sha256:a"] != report {
+		t.Error("expected first cache to have the entry")
+	}
+	if second.data["sha256:a"] != report {
+		t.Error("expected second cache to have the entry")
+	}
+}
+
+func TestMultiCache_missWhenNoCacheHasEntry(t *testing.T) {
+	m := NewMultiCache(newFakeCache(), newFakeCache())
+	if _, ok := m.Get("sha256:missing"); ok {
+		t.Error("expected a miss when no layer has the entry")
+	}
+}
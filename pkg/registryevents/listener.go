@@ -0,0 +1,114 @@
+package registryevents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"image-scan-webhook/pkg/anchore"
+	"image-scan-webhook/pkg/scanner"
+	"image-scan-webhook/pkg/transport"
+
+	"k8s.io/klog"
+)
+
+// prewarmRetryConfig controls how long prewarm waits for Anchore to finish
+// analyzing a freshly-registered image before giving up: backs off from 2s
+// up to 30s across 6 attempts (a little over a minute, worst case).
+var prewarmRetryConfig = transport.RetryConfig{
+	MaxAttempts: 6,
+	BaseDelay:   2 * time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// Listener handles registry push notifications, pre-warming Anchore and the
+// scan cache so the admission webhook can serve GetReport immediately
+// instead of blocking on a cold scan.
+type Listener struct {
+	anchore *anchore.AnchoreClient
+	scanner scanner.ImageScanner
+	cache   Cache
+	ttl     time.Duration
+}
+
+// NewListener builds a Listener. anchoreClient pre-registers pushed images;
+// imageScanner produces the report cached under the pushed digest.
+func NewListener(anchoreClient *anchore.AnchoreClient, imageScanner scanner.ImageScanner, cache Cache, ttl time.Duration) *Listener {
+	return &Listener{
+		anchore: anchoreClient,
+		scanner: imageScanner,
+		cache:   cache,
+		ttl:     ttl,
+	}
+}
+
+// ServeHTTP accepts a Docker Distribution / Harbor notification payload and
+// kicks off an async pre-warm for every "push" event it contains.
+func (l *Listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var notification Notification
+	if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode notification: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range notification.Events {
+		if event.Action != "push" {
+			continue
+		}
+		go l.prewarm(imageReference(event), event.Target.Digest)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func imageReference(event Event) string {
+	image := fmt.Sprintf("%s/%s", event.Request.Host, event.Target.Repository)
+	if event.Target.Tag != "" {
+		image += ":" + event.Target.Tag
+	}
+	return image
+}
+
+func (l *Listener) prewarm(image, digest string) {
+	prewarmedScans.Inc()
+
+	if err := l.anchore.AddImage(image); err != nil {
+		klog.Errorf("[RegistryEvents] failed to register %s with Anchore: %v", image, err)
+		prewarmFailures.Inc()
+		return
+	}
+
+	// Anchore only just learned about this image via AddImage above, so give
+	// it time to actually fetch and analyze the manifest before asking for a
+	// report: an immediate GetScanReport would just hit ErrNotAnalyzed.
+	var report *scanner.ScanReport
+	err := transport.Retry(prewarmRetryConfig, func(err error) bool {
+		return errors.Is(err, anchore.ErrNotAnalyzed)
+	}, func() error {
+		var reportErr error
+		report, reportErr = l.scanner.GetScanReport(image)
+		return reportErr
+	})
+	if err != nil {
+		klog.Errorf("[RegistryEvents] pre-warm scan failed for %s: %v", image, err)
+		prewarmFailures.Inc()
+		return
+	}
+
+	l.cache.Set(digest, report, l.ttl)
+	klog.Infof("[RegistryEvents] pre-warmed scan result for %s (digest %s)", image, digest)
+}
+
+// GetReport returns the pre-warmed scan report for digest, if one has
+// completed and not yet expired.
+func (l *Listener) GetReport(digest string) (*scanner.ScanReport, bool) {
+	report, ok := l.cache.Get(digest)
+	if ok {
+		cacheHits.Inc()
+	} else {
+		cacheMisses.Inc()
+	}
+	return report, ok
+}
@@ -0,0 +1,28 @@
+package registryevents
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	cacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "image_scan_webhook_cache_hits_total",
+		Help: "Admission-time scan reports served from the pre-warm cache.",
+	})
+
+	cacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "image_scan_webhook_cache_misses_total",
+		Help: "Admission-time scan reports not found in the pre-warm cache.",
+	})
+
+	prewarmedScans = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "image_scan_webhook_prewarmed_scans_total",
+		Help: "Scans kicked off asynchronously from a registry push event.",
+	})
+
+	prewarmFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "image_scan_webhook_prewarm_failures_total",
+		Help: "Pre-warm scans that failed to complete.",
+	})
+)
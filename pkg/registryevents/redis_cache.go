@@ -0,0 +1,60 @@
+package registryevents
+
+import (
+	"encoding/json"
+	"time"
+
+	"image-scan-webhook/pkg/scanner"
+
+	"k8s.io/klog"
+)
+
+// RedisClient is the subset of a Redis client RedisCache needs, so this
+// package doesn't have to depend on a specific Redis driver. Any client
+// satisfying this (e.g. go-redis's *redis.Client) can be passed in.
+type RedisClient interface {
+	Get(key string) (string, error)
+	Set(key string, value string, ttl time.Duration) error
+}
+
+// RedisCache shares pre-warmed scan reports across webhook replicas, keyed
+// by image digest.
+type RedisCache struct {
+	client RedisClient
+	prefix string
+}
+
+var _ Cache = (*RedisCache)(nil)
+
+// NewRedisCache wraps client, namespacing keys under prefix (e.g.
+// "image-scan-webhook:scan:").
+func NewRedisCache(client RedisClient, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+func (r *RedisCache) Get(digest string) (*scanner.ScanReport, bool) {
+	raw, err := r.client.Get(r.prefix + digest)
+	if err != nil || raw == "" {
+		return nil, false
+	}
+
+	var report scanner.ScanReport
+	if err := json.Unmarshal([]byte(raw), &report); err != nil {
+		klog.Errorf("[RegistryEvents] failed to unmarshal cached report for %s: %v", digest, err)
+		return nil, false
+	}
+
+	return &report, true
+}
+
+func (r *RedisCache) Set(digest string, report *scanner.ScanReport, ttl time.Duration) {
+	raw, err := json.Marshal(report)
+	if err != nil {
+		klog.Errorf("[RegistryEvents] failed to marshal report for %s: %v", digest, err)
+		return
+	}
+
+	if err := r.client.Set(r.prefix+digest, string(raw), ttl); err != nil {
+		klog.Errorf("[RegistryEvents] failed to write cache entry for %s: %v", digest, err)
+	}
+}
@@ -0,0 +1,30 @@
+// Package registryevents listens for registry push notifications (Docker
+// Distribution / Harbor webhook format) and pre-warms the scan for each
+// pushed image, so that by the time a Pod referencing it reaches the
+// admission webhook, the scan result is already cached.
+package registryevents
+
+// Notification is the envelope both Docker Distribution and Harbor POST to
+// their configured notification endpoint.
+type Notification struct {
+	Events []Event `json:"events"`
+}
+
+// Event is a single registry event. Only Action "push" triggers a pre-warm.
+type Event struct {
+	Action  string  `json:"action"`
+	Target  Target  `json:"target"`
+	Request Request `json:"request"`
+}
+
+// Target identifies the repository and digest a push event refers to.
+type Target struct {
+	Digest     string `json:"digest"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+}
+
+// Request carries the registry host that generated the event.
+type Request struct {
+	Host string `json:"host"`
+}
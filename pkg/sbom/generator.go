@@ -0,0 +1,73 @@
+// Package sbom generates software bills of materials for images using Syft
+// as a library, so OPA policy can deny on exact package/version tuples
+// without waiting for a CVE feed to catch up.
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/anchore/syft/syft"
+	"github.com/anchore/syft/syft/format"
+	"github.com/anchore/syft/syft/format/cyclonedxjson"
+
+	"image-scan-webhook/pkg/scanner"
+
+	"k8s.io/klog"
+)
+
+// Generator produces SBOMs for images, caching by digest so repeated
+// admission requests for the same image don't re-run Syft.
+type Generator struct {
+	mu    sync.Mutex
+	cache map[string]*scanner.SBOM
+}
+
+// NewGenerator returns a Generator with an empty cache.
+func NewGenerator() *Generator {
+	return &Generator{cache: make(map[string]*scanner.SBOM)}
+}
+
+// Generate returns the SBOM for image/digest, generating it with Syft on
+// first use and serving the cached copy on every subsequent call for the
+// same digest.
+func (g *Generator) Generate(image, digest string) (*scanner.SBOM, error) {
+	g.mu.Lock()
+	if cached, ok := g.cache[digest]; ok {
+		g.mu.Unlock()
+		return cached, nil
+	}
+	g.mu.Unlock()
+
+	klog.Infof("[SBOM] Generating SBOM for %s (digest %s)", image, digest)
+
+	ctx := context.Background()
+
+	src, err := syft.GetSource(ctx, image, syft.DefaultGetSourceConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load image %s for SBOM generation: %v", image, err)
+	}
+
+	s, err := syft.CreateSBOM(ctx, src, syft.DefaultCreateSBOMConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to catalog packages for %s: %v", image, err)
+	}
+
+	document, err := format.Encode(*s, cyclonedxjson.NewFormatEncoder())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode SBOM for %s: %v", image, err)
+	}
+
+	result := &scanner.SBOM{
+		Format:   "cyclonedx",
+		Digest:   digest,
+		Document: document,
+	}
+
+	g.mu.Lock()
+	g.cache[digest] = result
+	g.mu.Unlock()
+
+	return result, nil
+}
@@ -0,0 +1,47 @@
+package scanner
+
+import "image-scan-webhook/pkg/anchore"
+
+// AnchoreScanner adapts the existing AnchoreClient to the ImageScanner
+// interface, translating its native ScanReport into the backend-neutral one.
+type AnchoreScanner struct {
+	client *anchore.AnchoreClient
+}
+
+var _ ImageScanner = (*AnchoreScanner)(nil)
+
+// NewAnchoreScanner wraps an already-configured AnchoreClient.
+func NewAnchoreScanner(client *anchore.AnchoreClient) *AnchoreScanner {
+	return &AnchoreScanner{client: client}
+}
+
+func (s *AnchoreScanner) CheckImage(image string) (bool, error) {
+	return s.client.CheckImage(image)
+}
+
+func (s *AnchoreScanner) GetScanReport(image string) (*ScanReport, error) {
+	report, err := s.client.GetScanReport(image)
+	if err != nil {
+		return nil, err
+	}
+
+	var policyFindings []PolicyFinding
+	for _, finding := range report.GateFindings() {
+		policyFindings = append(policyFindings, PolicyFinding{
+			Gate:        finding.Gate,
+			Trigger:     finding.Trigger,
+			Action:      finding.Action,
+			Description: finding.Description,
+		})
+	}
+
+	return &ScanReport{
+		Backend:        "anchore",
+		Status:         report.Status,
+		PolicyFindings: policyFindings,
+		Metadata: ImageMetadata{
+			Digest: report.Digest,
+			Tags:   []string{image},
+		},
+	}, nil
+}
@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"fmt"
+
+	"image-scan-webhook/pkg/anchore"
+	"image-scan-webhook/pkg/registry"
+	"image-scan-webhook/pkg/transport"
+)
+
+// Config selects which scan backend(s) the webhook uses. Backends lists one
+// or more of "anchore", "grype", "trivy"; when more than one is given their
+// verdicts are combined via MultiScanner.
+type Config struct {
+	Backends []string
+
+	AnchoreURL          string
+	AnchoreToken        string
+	RegistryCredentials map[string]registry.Credential
+	TLS                 transport.Config
+
+	GrypeFailOnSeverity string
+
+	TrivyServerURL      string
+	TrivyFailOnSeverity string
+}
+
+// NewFromConfig builds the ImageScanner a deployment asked for.
+func NewFromConfig(cfg Config) (ImageScanner, error) {
+	if len(cfg.Backends) == 0 {
+		return nil, fmt.Errorf("no scanner backend configured")
+	}
+
+	var scanners []ImageScanner
+	for _, backend := range cfg.Backends {
+		s, err := newBackend(backend, cfg)
+		if err != nil {
+			return nil, err
+		}
+		scanners = append(scanners, s)
+	}
+
+	if len(scanners) == 1 {
+		return scanners[0], nil
+	}
+
+	return NewMultiScanner(scanners...), nil
+}
+
+func newBackend(backend string, cfg Config) (ImageScanner, error) {
+	switch backend {
+	case "anchore":
+		client, err := anchore.NewClient(cfg.AnchoreURL, cfg.AnchoreToken, cfg.RegistryCredentials, cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		return NewAnchoreScanner(client), nil
+	case "grype":
+		return NewGrypeScanner(cfg.GrypeFailOnSeverity, registry.NewClient(cfg.RegistryCredentials, cfg.TLS)), nil
+	case "trivy":
+		return NewTrivyScanner(cfg.TrivyServerURL, cfg.TrivyFailOnSeverity, registry.NewClient(cfg.RegistryCredentials, cfg.TLS)), nil
+	default:
+		return nil, fmt.Errorf("unknown scanner backend: %s", backend)
+	}
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"image-scan-webhook/pkg/registry"
+
+	"k8s.io/klog"
+)
+
+// GrypeScanner runs the `grype` CLI against an image reference and parses its
+// JSON output. It requires the grype binary to be present on PATH.
+type GrypeScanner struct {
+	// BinaryPath overrides the `grype` executable looked up on PATH, mostly
+	// useful for tests.
+	BinaryPath string
+	// FailOnSeverity is the minimum vulnerability severity that causes
+	// CheckImage to report a failing verdict (e.g. "medium").
+	FailOnSeverity string
+
+	registry *registry.Client
+}
+
+var _ ImageScanner = (*GrypeScanner)(nil)
+
+// NewGrypeScanner returns a scanner that shells out to grype, failing the
+// check on any vulnerability at or above failOnSeverity. registryClient
+// resolves each image's content digest for ScanReport.Metadata; pass nil to
+// leave Metadata.Digest empty.
+func NewGrypeScanner(failOnSeverity string, registryClient *registry.Client) *GrypeScanner {
+	return &GrypeScanner{BinaryPath: "grype", FailOnSeverity: failOnSeverity, registry: registryClient}
+}
+
+// resolveDigest best-effort resolves image's content digest: a failure here
+// shouldn't fail the whole scan, since Metadata.Digest is supplementary to
+// the vulnerability findings grype already produced.
+func (s *GrypeScanner) resolveDigest(image string) string {
+	if s.registry == nil {
+		return ""
+	}
+	digest, err := s.registry.GetDigest(image)
+	if err != nil {
+		klog.Warningf("[Grype] failed to resolve digest for %s: %v", image, err)
+		return ""
+	}
+	return digest
+}
+
+type grypeMatch struct {
+	Vulnerability struct {
+		ID       string `json:"id"`
+		Severity string `json:"severity"`
+		Fix      struct {
+			Versions []string `json:"versions"`
+		} `json:"fix"`
+	} `json:"vulnerability"`
+	Artifact struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"artifact"`
+}
+
+type grypeOutput struct {
+	Matches []grypeMatch `json:"matches"`
+}
+
+func (s *GrypeScanner) scan(image string) (*grypeOutput, error) {
+	cmd := exec.Command(s.BinaryPath, "-o", "json", image)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run grype against %s: %v", image, err)
+	}
+
+	var parsed grypeOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal grype output: %v", err)
+	}
+
+	return &parsed, nil
+}
+
+func (s *GrypeScanner) CheckImage(image string) (bool, error) {
+	out, err := s.scan(image)
+	if err != nil {
+		return false, err
+	}
+
+	for _, match := range out.Matches {
+		if severityAtLeast(match.Vulnerability.Severity, s.FailOnSeverity) {
+			klog.Infof("[Grype] %s failed: %s (%s) is %s", image, match.Artifact.Name, match.Vulnerability.ID, match.Vulnerability.Severity)
+			return false, fmt.Errorf("Scan result is FAILED")
+		}
+	}
+
+	return true, nil
+}
+
+func (s *GrypeScanner) GetScanReport(image string) (*ScanReport, error) {
+	out, err := s.scan(image)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScanReport{
+		Backend: "grype",
+		Status:  "pass",
+		Metadata: ImageMetadata{
+			Digest: s.resolveDigest(image),
+			Tags:   []string{image},
+		},
+	}
+
+	for _, match := range out.Matches {
+		if severityAtLeast(match.Vulnerability.Severity, s.FailOnSeverity) {
+			report.Status = "fail"
+		}
+
+		fixedIn := ""
+		if len(match.Vulnerability.Fix.Versions) > 0 {
+			fixedIn = strings.Join(match.Vulnerability.Fix.Versions, ", ")
+		}
+
+		report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+			ID:       match.Vulnerability.ID,
+			Package:  match.Artifact.Name,
+			Version:  match.Artifact.Version,
+			Severity: match.Vulnerability.Severity,
+			FixedIn:  fixedIn,
+		})
+	}
+
+	return report, nil
+}
+
+var severityRank = map[string]int{
+	"negligible": 0,
+	"low":        1,
+	"medium":     2,
+	"high":       3,
+	"critical":   4,
+}
+
+func severityAtLeast(severity, threshold string) bool {
+	if threshold == "" {
+		return false
+	}
+	return severityRank[strings.ToLower(severity)] >= severityRank[strings.ToLower(threshold)]
+}
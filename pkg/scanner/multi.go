@@ -0,0 +1,59 @@
+package scanner
+
+import "fmt"
+
+// MultiScanner fans an image out to several backends and combines their
+// verdicts, so an operator can run e.g. Anchore and Grype side by side
+// during a migration without the policy layer knowing about either.
+type MultiScanner struct {
+	scanners []ImageScanner
+}
+
+var _ ImageScanner = (*MultiScanner)(nil)
+
+// NewMultiScanner combines the given backends. CheckImage fails closed: any
+// backend failing or erroring fails the whole check.
+func NewMultiScanner(scanners ...ImageScanner) *MultiScanner {
+	return &MultiScanner{scanners: scanners}
+}
+
+func (m *MultiScanner) CheckImage(image string) (bool, error) {
+	for _, s := range m.scanners {
+		ok, err := s.CheckImage(image)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, fmt.Errorf("Scan result is FAILED")
+		}
+	}
+
+	return true, nil
+}
+
+// GetScanReport merges every backend's findings into a single report. The
+// combined Status is "fail" if any backend failed.
+func (m *MultiScanner) GetScanReport(image string) (*ScanReport, error) {
+	combined := &ScanReport{
+		Backend: "multi",
+		Status:  "pass",
+	}
+
+	for _, s := range m.scanners {
+		report, err := s.GetScanReport(image)
+		if err != nil {
+			return nil, err
+		}
+
+		if report.Status != "pass" {
+			combined.Status = "fail"
+		}
+		combined.Vulnerabilities = append(combined.Vulnerabilities, report.Vulnerabilities...)
+		combined.PolicyFindings = append(combined.PolicyFindings, report.PolicyFindings...)
+		if combined.Metadata.Digest == "" {
+			combined.Metadata = report.Metadata
+		}
+	}
+
+	return combined, nil
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeScanner struct {
+	checkOK   bool
+	checkErr  error
+	report    *ScanReport
+	reportErr error
+}
+
+func (f *fakeScanner) CheckImage(image string) (bool, error) {
+	return f.checkOK, f.checkErr
+}
+
+func (f *fakeScanner) GetScanReport(image string) (*ScanReport, error) {
+	return f.report, f.reportErr
+}
+
+func TestMultiScanner_CheckImage_allPass(t *testing.T) {
+	m := NewMultiScanner(&fakeScanner{checkOK: true}, &fakeScanner{checkOK: true})
+
+	ok, err := m.CheckImage("example.com/app:v1")
+	if err != nil {
+		t.Fatalf("CheckImage returned error: %v", err)
+	}
+	if !ok {
+		t.Error("expected CheckImage to pass when all backends pass")
+	}
+}
+
+func TestMultiScanner_CheckImage_failsClosedOnFailure(t *testing.T) {
+	m := NewMultiScanner(&fakeScanner{checkOK: true}, &fakeScanner{checkOK: false})
+
+	ok, err := m.CheckImage("example.com/app:v1")
+	if err == nil {
+		t.Fatal("expected an error when a backend fails")
+	}
+	if ok {
+		t.Error("expected CheckImage to fail when a backend fails")
+	}
+}
+
+func TestMultiScanner_CheckImage_failsClosedOnError(t *testing.T) {
+	backendErr := errors.New("backend unreachable")
+	m := NewMultiScanner(&fakeScanner{checkOK: true}, &fakeScanner{checkErr: backendErr})
+
+	_, err := m.CheckImage("example.com/app:v1")
+	if err != backendErr {
+		t.Errorf("err = %v, want %v", err, backendErr)
+	}
+}
+
+func TestMultiScanner_GetScanReport_combinesFindings(t *testing.T) {
+	a := &fakeScanner{report: &ScanReport{
+		Status:          "pass",
+		Vulnerabilities: []Vulnerability{{ID: "CVE-1"}},
+		Metadata:        ImageMetadata{Digest: "sha256:a"},
+	}}
+	b := &fakeScanner{report: &ScanReport{
+		Status:         "pass",
+		PolicyFindings: []PolicyFinding{{Gate: "dockerfile"}},
+	}}
+
+	m := NewMultiScanner(a, b)
+
+	report, err := m.GetScanReport("example.com/app:v1")
+	if err != nil {
+		t.Fatalf("GetScanReport returned error: %v", err)
+	}
+
+	if report.Status != "pass" {
+		t.Errorf("Status = %q, want pass", report.Status)
+	}
+	if len(report.Vulnerabilities) != 1 || report.Vulnerabilities[0].ID != "CVE-1" {
+		t.Errorf("Vulnerabilities = %+v, want one CVE-1 entry", report.Vulnerabilities)
+	}
+	if len(report.PolicyFindings) != 1 || report.PolicyFindings[0].Gate != "dockerfile" {
+		t.Errorf("PolicyFindings = %+v, want one dockerfile entry", report.PolicyFindings)
+	}
+	if report.Metadata.Digest != "sha256:a" {
+		t.Errorf("Metadata.Digest = %q, want sha256:a (from the first backend to report one)", report.Metadata.Digest)
+	}
+}
+
+func TestMultiScanner_GetScanReport_failsIfAnyBackendFails(t *testing.T) {
+	a := &fakeScanner{report: &ScanReport{Status: "pass"}}
+	b := &fakeScanner{report: &ScanReport{Status: "fail"}}
+
+	m := NewMultiScanner(a, b)
+
+	report, err := m.GetScanReport("example.com/app:v1")
+	if err != nil {
+		t.Fatalf("GetScanReport returned error: %v", err)
+	}
+	if report.Status != "fail" {
+		t.Errorf("Status = %q, want fail", report.Status)
+	}
+}
+
+func TestMultiScanner_GetScanReport_propagatesBackendError(t *testing.T) {
+	backendErr := errors.New("backend unreachable")
+	m := NewMultiScanner(&fakeScanner{report: &ScanReport{Status: "pass"}}, &fakeScanner{reportErr: backendErr})
+
+	if _, err := m.GetScanReport("example.com/app:v1"); err != backendErr {
+		t.Errorf("err = %v, want %v", err, backendErr)
+	}
+}
@@ -0,0 +1,63 @@
+// Package scanner defines a backend-neutral interface for image vulnerability
+// scanners, so that opaimagescanner can evaluate policy against Anchore,
+// Grype, Trivy, or any combination of them without caring which one produced
+// the result.
+package scanner
+
+// ImageScanner is implemented by every scan backend the webhook supports.
+type ImageScanner interface {
+	// CheckImage reports whether the given image reference passes the
+	// backend's policy/scan gate.
+	CheckImage(image string) (bool, error)
+	// GetScanReport returns the full, backend-neutral scan result for the
+	// given image reference.
+	GetScanReport(image string) (*ScanReport, error)
+}
+
+// ScanReport is the backend-neutral shape handed to the OPA policy as
+// `input.scan`, regardless of which ImageScanner produced it.
+type ScanReport struct {
+	Backend         string          `json:"backend"`
+	Status          string          `json:"status"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+	PolicyFindings  []PolicyFinding `json:"policyFindings,omitempty"`
+	Metadata        ImageMetadata   `json:"metadata"`
+	// SBOM is the CycloneDX/SPDX document generated for this image, when the
+	// webhook is configured to generate one. It's surfaced to the OPA policy
+	// as input.sbom so rules can key off exact package/version tuples.
+	SBOM *SBOM `json:"sbom,omitempty"`
+}
+
+// SBOM is a software bill of materials generated for an image, keyed by the
+// image digest so it's only ever generated once per image.
+type SBOM struct {
+	Format string `json:"format"` // "cyclonedx" or "spdx"
+	Digest string `json:"digest"` // image digest the SBOM was generated for
+	// Document is the raw, marshaled SBOM (CycloneDX or SPDX JSON).
+	Document []byte `json:"document"`
+}
+
+// Vulnerability is a single CVE/advisory match against a package in the image.
+type Vulnerability struct {
+	ID       string `json:"id"`
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	Severity string `json:"severity"`
+	FixedIn  string `json:"fixedIn,omitempty"`
+}
+
+// PolicyFinding is a backend-side policy gate trigger (e.g. an Anchore gate
+// action), kept alongside the normalized vulnerability list so operators
+// migrating from a single backend don't lose existing policy signals.
+type PolicyFinding struct {
+	Gate        string `json:"gate"`
+	Trigger     string `json:"trigger"`
+	Action      string `json:"action"`
+	Description string `json:"description,omitempty"`
+}
+
+// ImageMetadata carries the image facts the OPA policy commonly keys on.
+type ImageMetadata struct {
+	Digest string   `json:"digest"`
+	Tags   []string `json:"tags,omitempty"`
+}
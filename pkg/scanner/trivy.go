@@ -0,0 +1,153 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"image-scan-webhook/pkg/registry"
+
+	"k8s.io/klog"
+)
+
+// TrivyScanner talks to a Trivy server (`trivy server`) over its HTTP API,
+// rather than shelling out to the CLI on every admission.
+type TrivyScanner struct {
+	ServerURL      string
+	FailOnSeverity string
+	client         *http.Client
+
+	registry *registry.Client
+}
+
+var _ ImageScanner = (*TrivyScanner)(nil)
+
+// NewTrivyScanner points at a running Trivy server, e.g. "http://trivy:4954".
+// registryClient resolves each image's content digest for ScanReport.Metadata;
+// pass nil to leave Metadata.Digest empty.
+func NewTrivyScanner(serverURL, failOnSeverity string, registryClient *registry.Client) *TrivyScanner {
+	return &TrivyScanner{
+		ServerURL:      serverURL,
+		FailOnSeverity: failOnSeverity,
+		client:         &http.Client{},
+		registry:       registryClient,
+	}
+}
+
+// resolveDigest best-effort resolves image's content digest: a failure here
+// shouldn't fail the whole scan, since Metadata.Digest is supplementary to
+// the vulnerability findings Trivy already produced.
+func (s *TrivyScanner) resolveDigest(image string) string {
+	if s.registry == nil {
+		return ""
+	}
+	digest, err := s.registry.GetDigest(image)
+	if err != nil {
+		klog.Warningf("[Trivy] failed to resolve digest for %s: %v", image, err)
+		return ""
+	}
+	return digest
+}
+
+type trivyVulnerability struct {
+	VulnerabilityID  string `json:"VulnerabilityID"`
+	PkgName          string `json:"PkgName"`
+	InstalledVersion string `json:"InstalledVersion"`
+	FixedVersion     string `json:"FixedVersion"`
+	Severity         string `json:"Severity"`
+}
+
+type trivyResult struct {
+	Vulnerabilities []trivyVulnerability `json:"Vulnerabilities"`
+}
+
+type trivyScanResponse struct {
+	Results []trivyResult `json:"Results"`
+}
+
+func (s *TrivyScanner) scan(image string) (*trivyScanResponse, error) {
+	url := fmt.Sprintf("%s/v1/scan/image", s.ServerURL)
+	body, err := json.Marshal(map[string]interface{}{
+		"target": image,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request to Trivy: %v", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	klog.Infof("[Trivy] Scanning image %s against %s", image, s.ServerURL)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete request to Trivy: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("response from Trivy: %d", resp.StatusCode)
+	}
+
+	var parsed trivyScanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Trivy response: %v", err)
+	}
+
+	return &parsed, nil
+}
+
+func (s *TrivyScanner) CheckImage(image string) (bool, error) {
+	out, err := s.scan(image)
+	if err != nil {
+		return false, err
+	}
+
+	for _, result := range out.Results {
+		for _, vuln := range result.Vulnerabilities {
+			if severityAtLeast(vuln.Severity, s.FailOnSeverity) {
+				return false, fmt.Errorf("Scan result is FAILED")
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func (s *TrivyScanner) GetScanReport(image string) (*ScanReport, error) {
+	out, err := s.scan(image)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScanReport{
+		Backend: "trivy",
+		Status:  "pass",
+		Metadata: ImageMetadata{
+			Digest: s.resolveDigest(image),
+			Tags:   []string{image},
+		},
+	}
+
+	for _, result := range out.Results {
+		for _, vuln := range result.Vulnerabilities {
+			if severityAtLeast(vuln.Severity, s.FailOnSeverity) {
+				report.Status = "fail"
+			}
+
+			report.Vulnerabilities = append(report.Vulnerabilities, Vulnerability{
+				ID:       vuln.VulnerabilityID,
+				Package:  vuln.PkgName,
+				Version:  vuln.InstalledVersion,
+				Severity: vuln.Severity,
+				FixedIn:  vuln.FixedVersion,
+			})
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,52 @@
+package transport
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryConfig bounds the exponential backoff used by Retry.
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryConfig makes 4 attempts, backing off 250ms, 500ms and 1s
+// (each jittered down to as little as half) between them. The 5s MaxDelay
+// cap is not reached at this attempt count.
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts: 4,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// Retry calls fn until it succeeds, shouldRetry(err) returns false, or
+// cfg.MaxAttempts is reached, sleeping an exponentially increasing,
+// jittered delay between attempts.
+func Retry(cfg RetryConfig, shouldRetry func(error) bool, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < cfg.MaxAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		if !shouldRetry(err) {
+			return err
+		}
+		if attempt == cfg.MaxAttempts-1 {
+			break
+		}
+		time.Sleep(backoff(cfg, attempt))
+	}
+	return err
+}
+
+func backoff(cfg RetryConfig, attempt int) time.Duration {
+	delay := cfg.BaseDelay << uint(attempt)
+	if delay > cfg.MaxDelay || delay <= 0 {
+		delay = cfg.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay) + 1))
+	return delay/2 + jitter/2
+}
@@ -0,0 +1,105 @@
+package transport
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry_succeedsWithoutRetrying(t *testing.T) {
+	calls := 0
+	err := Retry(DefaultRetryConfig, func(error) bool { return true }, func() error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestRetry_retriesUntilSuccess(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	calls := 0
+	err := Retry(cfg, func(error) bool { return true }, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry returned error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetry_stopsWhenShouldRetryReturnsFalse(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	calls := 0
+	nonRetryable := errors.New("not retryable")
+	err := Retry(cfg, func(error) bool { return false }, func() error {
+		calls++
+		return nonRetryable
+	})
+	if err != nonRetryable {
+		t.Errorf("err = %v, want %v", err, nonRetryable)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestRetry_givesUpAfterMaxAttempts(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	calls := 0
+	retryable := errors.New("always retryable")
+	err := Retry(cfg, func(error) bool { return true }, func() error {
+		calls++
+		return retryable
+	})
+	if err != retryable {
+		t.Errorf("err = %v, want %v", err, retryable)
+	}
+	if calls != cfg.MaxAttempts {
+		t.Errorf("calls = %d, want %d", calls, cfg.MaxAttempts)
+	}
+}
+
+func TestBackoff_boundedByMaxDelay(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	for attempt := 0; attempt < cfg.MaxAttempts; attempt++ {
+		delay := backoff(cfg, attempt)
+		if delay < 0 || delay > cfg.MaxDelay {
+			t.Errorf("backoff(%d) = %v, want within [0, %v]", attempt, delay, cfg.MaxDelay)
+		}
+	}
+}
+
+func TestBackoff_growsWithAttempt(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Hour}
+
+	// With jitter, a single sample isn't reliable, so check that each sample
+	// falls within [BaseDelay<<attempt / 2, BaseDelay<<attempt]: that window's
+	// upper bound grows with attempt, and each window starts where the
+	// previous one ends.
+	prev := time.Duration(0)
+	for attempt := 0; attempt < 5; attempt++ {
+		upperBound := cfg.BaseDelay << uint(attempt)
+		if upperBound <= prev {
+			t.Fatalf("expected upper bound to grow at attempt %d: %v <= %v", attempt, upperBound, prev)
+		}
+		if delay := backoff(cfg, attempt); delay < upperBound/2 || delay > upperBound {
+			t.Errorf("backoff(%d) = %v, want within [%v, %v]", attempt, delay, upperBound/2, upperBound)
+		}
+		prev = upperBound
+	}
+}